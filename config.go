@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zxiiro/license-header-checker/pkg/comments"
+)
+
+// configFileName is the default config file lhc looks for in the current
+// directory, modeled on skywalking-eyes' .licenserc.yaml.
+const configFileName = ".license-checker.yaml"
+
+// Config is the on-disk shape of .license-checker.yaml. It lets users
+// declare the license to enforce, which paths to scan, and per-language
+// comment styles (for extensions pkg/comments doesn't already know about),
+// instead of threading everything through CLI flags.
+type Config struct {
+	License struct {
+		SPDX string `yaml:"spdx-id"`
+	} `yaml:"license"`
+	PathsInclude  []string                `yaml:"paths-include"`
+	PathsExclude  []string                `yaml:"paths-exclude"`
+	CommentStyles map[string]CommentStyle `yaml:"comment-styles"`
+}
+
+// CommentStyle is the YAML shape of a comment-styles entry, keyed by file
+// extension (including the leading dot, e.g. ".proto"), mirroring
+// comments.Style.
+type CommentStyle struct {
+	Line       []string `yaml:"line"`
+	BlockStart string   `yaml:"block-start"`
+	BlockEnd   string   `yaml:"block-end"`
+}
+
+// loadConfig reads and parses the config file at path. It returns
+// (nil, nil) if path does not exist, since the config file is optional and
+// callers are expected to fall back to CLI flags in that case.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	for ext, s := range cfg.CommentStyles {
+		comments.Register(ext, comments.Style{Line: s.Line, BlockStart: s.BlockStart, BlockEnd: s.BlockEnd})
+	}
+
+	return &cfg, nil
+}
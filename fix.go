@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+
+	"github.com/zxiiro/license-header-checker/pkg/comments"
+	"github.com/zxiiro/license-header-checker/pkg/licenses"
+)
+
+// bom is the UTF-8 byte order mark some editors prepend to files.
+const bom = "\uFEFF"
+
+// licenseTemplateText returns the raw, human-readable text of a license
+// template, for rendering into an inserted header. Unlike fetchLicense,
+// which uppercases and collapses whitespace for comparison purposes, this
+// preserves the original formatting. name may be an SPDX identifier or a
+// path to a license file.
+func licenseTemplateText(name string) string {
+	if t, err := licenses.Lookup(name); err == nil {
+		return t.Header
+	}
+	data, err := os.ReadFile(name)
+	check(err)
+	return string(data)
+}
+
+// buildHeader renders templateText as a header comment in style. When
+// spdxID is non-empty it is prepended as an SPDX-License-Identifier line,
+// which scanHeader reports as the file's declared identifier; when it is
+// empty (no SPDX id could be resolved for the configured license) the line
+// is omitted rather than declaring a bogus identifier.
+func buildHeader(style comments.Style, spdxID string, templateText string) []string {
+	body := strings.Split(strings.TrimRight(templateText, "\n"), "\n")
+
+	var spdxLine string
+	if spdxID != "" {
+		spdxLine = "SPDX-License-Identifier: " + spdxID
+	}
+
+	var header []string
+	switch {
+	case style.BlockStart != "":
+		header = append(header, style.BlockStart)
+		if spdxLine != "" {
+			header = append(header, spdxLine, "")
+		}
+		header = append(header, body...)
+		header = append(header, style.BlockEnd)
+	case len(style.Line) > 0:
+		token := style.Line[0]
+		if spdxLine != "" {
+			header = append(header, token+" "+spdxLine)
+			header = append(header, token)
+		}
+		for _, l := range body {
+			header = append(header, strings.TrimRight(token+" "+l, " "))
+		}
+	default:
+		// Unknown language: fall back to a plain comment-less block so the
+		// header is still present, even if it won't parse as a comment.
+		if spdxLine != "" {
+			header = append(header, spdxLine, "")
+		}
+		header = append(header, body...)
+	}
+	header = append(header, "")
+	return header
+}
+
+// insertionPoint returns the line index after which the header should be
+// inserted, skipping a leading shebang, XML declaration, and Go build
+// tags, which must stay first in the file.
+func insertionPoint(lines []string) int {
+	i := 0
+	if i < len(lines) && strings.HasPrefix(lines[i], "#!") {
+		i++
+	}
+	if i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "<?xml") {
+		i++
+	}
+	for i < len(lines) && (strings.HasPrefix(lines[i], "//go:build") ||
+		strings.HasPrefix(lines[i], "// +build")) {
+		i++
+		// A blank line conventionally separates build tags from the
+		// package clause; keep it above the inserted header.
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			i++
+		}
+	}
+	return i
+}
+
+// unifiedDiff renders a minimal unified diff for a pure insertion of
+// inserted at line pos (0-indexed) of original, which is all fixFile ever
+// produces.
+func unifiedDiff(path string, original []string, inserted []string, pos int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+	fmt.Fprintf(&b, "@@ -%d,0 +%d,%d @@\n", pos, pos+1, len(inserted))
+	for _, l := range inserted {
+		fmt.Fprintf(&b, "+%s\n", l)
+	}
+	return b.String()
+}
+
+// fixFile inserts a license header into path if it has no accepted header
+// or does not declare spdxID as its SPDX-License-Identifier, returning the unified diff of the change (empty if no change was
+// needed). When inPlace is true the file is rewritten atomically;
+// otherwise the caller is expected to just display the diff.
+func fixFile(path string, style comments.Style, spdxID string, templateText string, acceptedLicenses []License, inPlace bool) (string, error) {
+	headerText, declaredSPDX := scanHeader(path)
+	if accepted_license(headerText, acceptedLicenses) != "" && strings.EqualFold(declaredSPDX, spdxID) {
+		return "", nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	hadBOM := strings.HasPrefix(string(data), bom)
+	content := strings.TrimPrefix(string(data), bom)
+
+	lines := strings.Split(content, "\n")
+	pos := insertionPoint(lines)
+	header := buildHeader(style, spdxID, templateText)
+
+	var out []string
+	out = append(out, lines[:pos]...)
+	out = append(out, header...)
+	out = append(out, lines[pos:]...)
+
+	diff := unifiedDiff(path, lines, header, pos)
+
+	if inPlace {
+		tmp, err := os.CreateTemp(dirOf(path), ".lhc-fix-*")
+		if err != nil {
+			return diff, err
+		}
+		defer os.Remove(tmp.Name())
+
+		w := bufio.NewWriter(tmp)
+		if hadBOM {
+			w.WriteString(bom)
+		}
+		w.WriteString(strings.Join(out, "\n"))
+		if err := w.Flush(); err != nil {
+			tmp.Close()
+			return diff, err
+		}
+		if err := tmp.Close(); err != nil {
+			return diff, err
+		}
+		if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+			return diff, err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return diff, err
+		}
+	}
+
+	return diff, nil
+}
+
+// dirOf returns the directory containing path, for placing the temp file
+// used by an atomic in-place fix on the same filesystem as path.
+func dirOf(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return "."
+	}
+	return path[:i]
+}
+
+// runFix implements the `lhc fix` subcommand: for every scanned file
+// missing an accepted license header or the configured SPDX identifier,
+// prepend one rendered from the configured license template.
+func runFix(args []string) {
+	fixFlags := flag.NewFlagSet("fix", flag.ExitOnError)
+	configPtr := fixFlags.String("config", configFileName,
+		"Path to a .license-checker.yaml config file.")
+	directoryPtr := fixFlags.String("directory", ".",
+		"Directory to search for files.")
+	excludePtr := fixFlags.String("exclude", "",
+		"Comma-separated list of doublestar glob patterns to exclude, "+
+			"e.g. 'vendor/**,**/*_generated.go'.")
+	licensePtr := fixFlags.String("license", "license.txt",
+		"License file (or SPDX ID) whose text is used as the header template.")
+	dryRunPtr := fixFlags.Bool("dry-run", false,
+		"Print a unified diff of the headers that would be inserted, without writing.")
+	inPlacePtr := fixFlags.Bool("in-place", false,
+		"Write missing headers back to each file.")
+	noGitignorePtr := fixFlags.Bool("no-gitignore", false,
+		"Do not skip files matched by .gitignore.")
+	fixFlags.Parse(args)
+
+	cfg, err := loadConfig(*configPtr)
+	check(err)
+
+	patterns := fixFlags.Args()
+	exclude_patterns := *excludePtr
+	license_file := *licensePtr
+	if cfg != nil {
+		if len(patterns) == 0 {
+			patterns = cfg.PathsInclude
+		}
+		if exclude_patterns == "" && len(cfg.PathsExclude) > 0 {
+			exclude_patterns = strings.Join(cfg.PathsExclude, ",")
+		}
+		if *licensePtr == "license.txt" && cfg.License.SPDX != "" {
+			license_file = cfg.License.SPDX
+		}
+	}
+
+	templateText := licenseTemplateText(license_file)
+	acceptedLicenses := []License{{license_file, fetchLicense(license_file)}}
+
+	// Resolve the SPDX identifier to declare in the inserted header,
+	// preferring an explicit config value, then falling back to
+	// license_file itself when it is already a valid SPDX expression. If
+	// neither resolves (e.g. license_file is a path to a license file),
+	// spdxID stays empty and buildHeader omits the identifier line instead
+	// of declaring a bogus one.
+	spdxID := ""
+	if cfg != nil {
+		spdxID = cfg.License.SPDX
+	}
+	if spdxID == "" {
+		spdxID = license_file
+	}
+	spdxID = resolveSPDXID(spdxID)
+
+	var ignoreMatcher gitignore.Matcher
+	if !*noGitignorePtr {
+		ignoreMatcher = loadGitignore(*directoryPtr)
+	}
+	checkFiles := findFiles(*directoryPtr, patterns, ignoreMatcher)
+
+	fixed := 0
+	for _, file := range checkFiles {
+		if exclude_patterns != "" && exclude(*directoryPtr, file, strings.Split(exclude_patterns, ",")) {
+			continue
+		}
+
+		style := defaultStyle
+		if s, ok := comments.StyleForFile(file); ok {
+			style = s
+		}
+
+		diff, err := fixFile(file, style, spdxID, templateText, acceptedLicenses, *inPlacePtr && !*dryRunPtr)
+		check(err)
+		if diff == "" {
+			continue
+		}
+
+		fixed++
+		if *dryRunPtr {
+			fmt.Print(diff)
+		} else if *inPlacePtr {
+			fmt.Println("fixed", file)
+		}
+	}
+
+	fmt.Printf("License Fix Total: %d, Fixed: %d\n", len(checkFiles), fixed)
+}
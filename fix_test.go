@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zxiiro/license-header-checker/pkg/comments"
+)
+
+func TestBuildHeaderOmitsSPDXLineWhenIDUnknown(t *testing.T) {
+	style := comments.Style{BlockStart: "/*", BlockEnd: "*/"}
+
+	header := buildHeader(style, "", "My License Text")
+	if strings.Contains(strings.Join(header, "\n"), "SPDX-License-Identifier") {
+		t.Errorf("buildHeader with empty spdxID must not emit an identifier line, got:\n%s",
+			strings.Join(header, "\n"))
+	}
+
+	header = buildHeader(style, "MIT", "My License Text")
+	if !strings.Contains(header[1], "SPDX-License-Identifier: MIT") {
+		t.Errorf("buildHeader with spdxID=MIT should declare it, got:\n%s",
+			strings.Join(header, "\n"))
+	}
+}
+
+func TestBuildHeaderLineStyle(t *testing.T) {
+	style := comments.Style{Line: []string{"#"}}
+
+	header := buildHeader(style, "MIT", "line one\nline two")
+	want := []string{"# SPDX-License-Identifier: MIT", "#", "# line one", "# line two", ""}
+	if strings.Join(header, "|") != strings.Join(want, "|") {
+		t.Errorf("buildHeader(line style) = %q, want %q", header, want)
+	}
+}
+
+func TestInsertionPointSkipsShebangAndBuildTags(t *testing.T) {
+	lines := []string{"#!/usr/bin/env bash", "", "echo hi"}
+	if got := insertionPoint(lines); got != 1 {
+		t.Errorf("insertionPoint(shebang) = %d, want 1", got)
+	}
+
+	lines = []string{"//go:build linux", "", "package main"}
+	if got := insertionPoint(lines); got != 2 {
+		t.Errorf("insertionPoint(build tag) = %d, want 2", got)
+	}
+
+	lines = []string{"package main"}
+	if got := insertionPoint(lines); got != 0 {
+		t.Errorf("insertionPoint(plain) = %d, want 0", got)
+	}
+}
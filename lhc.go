@@ -29,27 +29,50 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 	"unicode"
 
-	"github.com/zxiiro/license-header-checker/licenses"
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+
+	"github.com/zxiiro/license-header-checker/pkg/comments"
+	"github.com/zxiiro/license-header-checker/pkg/licenses"
 )
 
 var LICENSE_HEADER_LINES_MAX = 50
 var VERSION = "0.1.0"
 
+// fileResult is the outcome of scanning a single file: the license
+// accepted_license concluded (if any), the SPDX-License-Identifier value
+// declared in the file (if any), and whether each check passed. It is
+// produced once per file by the worker pool and reused both for report
+// output and for the --sbom writer.
+type fileResult struct {
+	path       string
+	license    string
+	spdxID     string
+	headerPass bool
+	spdxPass   bool
+}
+
 type License struct {
 	Name string
 	Text string
 }
 
-// Compare a license header with an approved list of license headers.
-// Returns the name of the license that was approved. Else "".
+// Compare a license header with an approved list of license headers,
+// tolerating re-flowed whitespace, punctuation, and boilerplate
+// differences via licenses.Match. Returns the name of the license that
+// was approved. Else "".
 func accepted_license(check string, approved []License) string {
 	for _, i := range approved {
-		if strings.Contains(check, i.Text) {
+		if name, _ := licenses.Match(check, i.Text); name != "" {
 			return i.Name
 		}
 	}
@@ -65,50 +88,38 @@ func check(e error) {
 	}
 }
 
-func checkSPDX(license string, filename string) bool {
-	file, err := os.Open(filename)
-	check(err)
-	defer file.Close()
-	scanner := bufio.NewScanner(file)
-
-	i := 0
-	for scanner.Scan() {
-		// Read only the first few lines to not read entire code file
-		i++
-		if i > LICENSE_HEADER_LINES_MAX {
-			break
-		}
-
-		s := strings.ToUpper(scanner.Text())
-		if strings.Contains(s, "SPDX-LICENSE-IDENTIFIER:") {
-			spdx := stripSpaces(strings.SplitN(s, ":", 2)[1])
-			if spdx == license {
-				return true
-			} else {
-				return false
-			}
-		}
-	}
-
-	return false
-}
-
-func exclude(path string, excludes []string) bool {
-	for i := range excludes {
-		if strings.Contains(path, excludes[i]) {
+// exclude reports whether path (relative to directory) matches any of the
+// doublestar glob patterns in excludes, e.g. "vendor/**" or
+// "**/*_generated.go".
+func exclude(directory, path string, excludes []string) bool {
+	rel := relSlash(directory, path)
+	for _, p := range excludes {
+		if ok, _ := doublestar.Match(p, rel); ok {
 			return true
 		}
 	}
 	return false
 }
 
-func findFiles(directory string, patterns []string) []string {
+// findFiles walks directory and returns every file whose path (relative
+// to directory) matches one of the doublestar glob patterns, skipping
+// anything ignoreMatcher says is gitignored.
+func findFiles(directory string, patterns []string, ignoreMatcher gitignore.Matcher) []string {
 	var files []string
-	filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
-		if info.IsDir() {
-			for _, p := range patterns {
-				f, _ := filepath.Glob(filepath.Join(path, p))
-				files = append(files, f...)
+	filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+
+		rel := relSlash(directory, path)
+		if ignoreMatcher != nil && ignoreMatcher.Match(strings.Split(rel, "/"), false) {
+			return nil
+		}
+
+		for _, p := range patterns {
+			if ok, _ := doublestar.Match(p, rel); ok {
+				files = append(files, path)
+				break
 			}
 		}
 		return nil
@@ -116,37 +127,110 @@ func findFiles(directory string, patterns []string) []string {
 	return files
 }
 
-// fetchLicense from file and return license text.
+// relSlash returns path relative to directory using forward slashes, as
+// expected by doublestar and go-git's gitignore matcher regardless of
+// platform.
+func relSlash(directory, path string) string {
+	rel, err := filepath.Rel(directory, path)
+	check(err)
+	return filepath.ToSlash(rel)
+}
+
+// loadGitignore reads .gitignore (and any nested .gitignore files) under
+// directory and returns a matcher for them, or nil if none exist.
+func loadGitignore(directory string) gitignore.Matcher {
+	billyFS := osfs.New(directory)
+	patterns, err := gitignore.ReadPatterns(billyFS, nil)
+	check(err)
+	if len(patterns) == 0 {
+		return nil
+	}
+	return gitignore.NewMatcher(patterns)
+}
+
+// defaultStyle is used for license template files (which aren't real
+// source files) and for any extension missing from the comments registry,
+// preserving the set of tokens lhc originally understood.
+var defaultStyle = comments.Style{Line: []string{"#", "//"}, BlockStart: "/*", BlockEnd: "*/"}
+
+// resolveSPDXID returns the canonical SPDX identifier for name if it
+// resolves to one of the embedded licenses.Lookup templates, or "" if name
+// is something else, such as a path to a license file.
+func resolveSPDXID(name string) string {
+	if t, err := licenses.Lookup(name); err == nil {
+		return t.ID
+	}
+	return ""
+}
+
+// fetchLicense from file and return license text. filename may also be an
+// SPDX identifier (optionally with a WITH exception, e.g. "Apache-2.0
+// WITH LLVM-exception"), in which case the embedded SPDX template's
+// header text is used instead of reading a file.
 func fetchLicense(filename string) string {
-	comment, multilineComment := false, false
-	licenseText := ""
+	style := defaultStyle
 
 	var scanner *bufio.Scanner
-	if filename == "Apache-2.0" {
-		scanner = bufio.NewScanner(strings.NewReader(license.APACHE_20_LICENSE))
-	} else if filename == "Apache-2.0-ASF" {
-		scanner = bufio.NewScanner(strings.NewReader(license.APACHE_20_LICENSE_ASF))
-	} else if filename == "EPL-1.0" {
-		scanner = bufio.NewScanner(strings.NewReader(license.EPL_10_LICENSE))
-	} else if filename == "MIT" {
-		scanner = bufio.NewScanner(strings.NewReader(license.MIT_LICENSE))
+	comment := false
+	if t, err := licenses.Lookup(filename); err == nil {
+		scanner = bufio.NewScanner(strings.NewReader(t.Header))
 	} else {
+		if s, ok := comments.StyleForFile(filename); ok {
+			style = s
+		}
+
 		file, err := os.Open(filename)
 		check(err)
 		defer file.Close()
 
-		// Read the first 2 bytes to decide if it is a comment string
-		b := make([]byte, 2)
-		_, err = file.Read(b)
-		check(err)
-		if isComment(string(b)) {
-			comment = true
-		}
-		file.Seek(0, 0) // Reset so we can read the full file next
-
+		comment = isComment(style, leadingBytes(file))
 		scanner = bufio.NewScanner(file)
 	}
 
+	headerText, _ := scanLicenseLines(scanner, style, comment)
+	return headerText
+}
+
+// scanHeader opens filename once and returns both the normalized license
+// header text (as fetchLicense would) and the value of any
+// SPDX-License-Identifier comment found within the first
+// LICENSE_HEADER_LINES_MAX lines, so callers checking both never need to
+// open the file twice.
+func scanHeader(filename string) (headerText string, spdxID string) {
+	style := defaultStyle
+	if s, ok := comments.StyleForFile(filename); ok {
+		style = s
+	}
+
+	file, err := os.Open(filename)
+	check(err)
+	defer file.Close()
+
+	comment := isComment(style, leadingBytes(file))
+	scanner := bufio.NewScanner(file)
+
+	return scanLicenseLines(scanner, style, comment)
+}
+
+// leadingBytes returns the first 2 bytes of file, used to decide whether
+// it opens with a comment, then rewinds file so the full contents can
+// still be scanned.
+func leadingBytes(file *os.File) string {
+	b := make([]byte, 2)
+	_, err := file.Read(b)
+	check(err)
+	file.Seek(0, 0)
+	return string(b)
+}
+
+// scanLicenseLines reads up to LICENSE_HEADER_LINES_MAX lines from
+// scanner, stripping comment markers per style when comment is true, and
+// returns the normalized header text together with the value of any
+// SPDX-License-Identifier line encountered.
+func scanLicenseLines(scanner *bufio.Scanner, style comments.Style, comment bool) (headerText string, spdxID string) {
+	multilineComment := false
+	licenseText := ""
+
 	i := 0
 	for scanner.Scan() {
 		// Read only the first few lines to not read entire code file
@@ -161,46 +245,44 @@ func fetchLicense(filename string) string {
 		// Some projects DO NOT explicitly print this statement so ignore.
 		s = strings.Replace(s, "ALL RIGHTS RESERVED.", "", -1)
 
-		if ignoreComment(s) {
+		if spdxID == "" && strings.Contains(s, "SPDX-LICENSE-IDENTIFIER:") {
+			spdxID = stripSpaces(strings.SplitN(s, ":", 2)[1])
+		}
+
+		if ignoreComment(style, s) {
 			continue
 		}
 
 		if comment == true {
-			if strings.HasPrefix(s, "/*") {
+			if style.BlockStart != "" && strings.HasPrefix(s, style.BlockStart) {
 				multilineComment = true
-			} else if strings.Contains(s, "*/") {
+			} else if style.BlockEnd != "" && strings.Contains(s, style.BlockEnd) {
 				multilineComment = false
 			}
 
-			if !multilineComment && !isComment(s) ||
+			if !multilineComment && !isComment(style, s) ||
 				// EPL headers can contain contributors list.
 				strings.Contains(strings.ToUpper(s), " * CONTRIBUTORS:") {
 				continue
 			}
 
-			s = trimComment(s)
+			s = trimComment(style, s)
 		}
 
-		licenseText += s
+		licenseText += s + " "
 	}
 
-	return stripSpaces(licenseText)
+	return strings.Join(strings.Fields(licenseText), " "), spdxID
 }
 
-// Check if a string is a comment line.
-func isComment(str string) bool {
-	if !strings.HasPrefix(str, "#") &&
-		!strings.HasPrefix(str, "//") &&
-		!strings.HasPrefix(str, "/*") {
-		return false
-	}
-
-	return true
+// Check if a string is a comment line in the given style.
+func isComment(style comments.Style, str string) bool {
+	return comments.IsComment(style, str)
 }
 
 // Ignore certain lines containing key strings
-func ignoreComment(str string) bool {
-	s := strings.ToUpper(trimComment(str))
+func ignoreComment(style comments.Style, str string) bool {
+	s := strings.ToUpper(trimComment(style, str))
 	if strings.HasPrefix(s, "#!") ||
 		strings.HasPrefix(s, "COPYRIGHT") ||
 		strings.HasPrefix(s, "SPDX-LICENSE-IDENTIFIER") ||
@@ -223,35 +305,50 @@ func stripSpaces(str string) string {
 }
 
 // Trim the comment prefix from string.
-func trimComment(str string) string {
-	str = strings.TrimLeft(str, "#")
-	str = strings.TrimLeft(str, "//")
-	str = strings.TrimLeft(str, "/*")
-	str = strings.TrimLeft(str, " *")
-	str = strings.Split(str, "*/")[0]
-	str = strings.TrimLeft(str, "*")
-	return str
+func trimComment(style comments.Style, str string) string {
+	return comments.TrimComment(style, str)
 }
 
 // Usage prints a statement to explain how to use this command.
 func usage() {
 	fmt.Printf("Usage: %s [OPTIONS] [FILE]...\n", os.Args[0])
-	fmt.Printf("Compare FILE with an expected license header.\n")
+	fmt.Printf("       %s fix [OPTIONS] [FILE]...\n", os.Args[0])
+	fmt.Printf("Compare FILE with an expected license header, or with `fix`, insert one.\n")
 	fmt.Printf("\nOptions:\n")
 	flag.PrintDefaults()
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fix" {
+		runFix(os.Args[2:])
+		return
+	}
+
+	configPtr := flag.String("config", configFileName,
+		"Path to a .license-checker.yaml config file.")
 	directoryPtr := flag.String("directory", ".",
 		"Directory to search for files.")
 	disableSPDXPtr := flag.Bool("disable-spdx", false,
 		"Verify SDPX identifier matches license.")
 	excludePtr := flag.String("exclude", "",
-		"Comma-separated list of paths to exclude. The code will search for "+
-			"paths containing this pattern. For example '/yang/gen/' is "+
-			"'**/yang/gen/**'.")
+		"Comma-separated list of doublestar glob patterns to exclude, "+
+			"e.g. 'vendor/**,**/*_generated.go'.")
+	noGitignorePtr := flag.Bool("no-gitignore", false,
+		"Do not skip files matched by .gitignore.")
 	licensePtr := flag.String("license", "license.txt",
-		"Comma-separated list of license files to compare against.")
+		"Comma-separated list of license files or supported SPDX identifiers "+
+			"(e.g. 'MIT', 'Apache-2.0 WITH LLVM-exception'; see -list-licenses) "+
+			"to compare against.")
+	listLicensesPtr := flag.Bool("list-licenses", false,
+		"Print every supported SPDX identifier and exit.")
+	sbomPtr := flag.String("sbom", "",
+		"Write an SPDX 2.3 SBOM of scanned files and detected licenses to this path.")
+	sbomFormatPtr := flag.String("sbom-format", "tag-value",
+		"SBOM format to use with --sbom: \"tag-value\" or \"json\".")
+	jobsPtr := flag.Int("j", runtime.NumCPU(),
+		"Number of files to scan concurrently.")
+	formatPtr := flag.String("format", "text",
+		"Output format: text, json, junit, or github.")
 	versionPtr := flag.Bool("version", false, "Print version")
 
 	flag.Usage = usage
@@ -262,61 +359,102 @@ func main() {
 		os.Exit(0)
 	}
 
-	fmt.Println("Search Patterns:", flag.Args())
+	if *listLicensesPtr {
+		for _, id := range licenses.IDs() {
+			fmt.Println(id)
+		}
+		os.Exit(0)
+	}
+
+	cfg, err := loadConfig(*configPtr)
+	check(err)
+
+	patterns := flag.Args()
+	exclude_patterns := *excludePtr
+	license_files := *licensePtr
+	if cfg != nil {
+		if len(patterns) == 0 {
+			patterns = cfg.PathsInclude
+		}
+		if exclude_patterns == "" && len(cfg.PathsExclude) > 0 {
+			exclude_patterns = strings.Join(cfg.PathsExclude, ",")
+		}
+		if *licensePtr == "license.txt" && cfg.License.SPDX != "" {
+			license_files = cfg.License.SPDX
+		}
+	}
+
+	fmt.Println("Search Patterns:", patterns)
 
 	var accepted_licenses []License
-	for _, l := range strings.Split(*licensePtr, ",") {
+	for _, l := range strings.Split(license_files, ",") {
 		license := License{l, fetchLicense(l)}
 		accepted_licenses = append(accepted_licenses, license)
-
-		if l == "Apache-2.0" {
-			license := License{l, fetchLicense("Apache-2.0-ASF")}
-			accepted_licenses = append(accepted_licenses, license)
-		}
 	}
-	checkFiles := findFiles(*directoryPtr, flag.Args())
-
-	ignore, miss, pass, spdx_miss, spdx_pass := 0, 0, 0, 0, 0
-	for _, file := range checkFiles {
+	var ignoreMatcher gitignore.Matcher
+	if !*noGitignorePtr {
+		ignoreMatcher = loadGitignore(*directoryPtr)
+	}
+	allFiles := findFiles(*directoryPtr, patterns, ignoreMatcher)
 
-		if *excludePtr != "" && exclude(file, strings.Split(*excludePtr, ",")) {
+	var checkFiles []string
+	ignore := 0
+	for _, file := range allFiles {
+		if exclude_patterns != "" && exclude(*directoryPtr, file, strings.Split(exclude_patterns, ",")) {
 			ignore++
 			continue
 		}
+		checkFiles = append(checkFiles, file)
+	}
 
-		headerText := fetchLicense(file)
-		license := accepted_license(headerText, accepted_licenses)
-		result := ""
+	results := scanFiles(checkFiles, *jobsPtr, accepted_licenses, *disableSPDXPtr)
+	check(printResults(results, *formatPtr, *disableSPDXPtr))
 
-		if license != "" {
-			result = result + "✔"
+	miss, pass, spdx_miss, spdx_pass := 0, 0, 0, 0
+	for _, r := range results {
+		if r.headerPass {
 			pass++
 		} else {
-			result = result + "✘"
 			miss++
 		}
-
 		if !*disableSPDXPtr {
-			if checkSPDX(license, file) {
-				result = result + "✔"
+			if r.spdxPass {
 				spdx_pass++
 			} else {
-				result = result + "✘"
 				spdx_miss++
 			}
 		}
-		fmt.Println(result, file)
 	}
 
 	fmt.Printf("License Total: %d, Ignored: %d, Missing: %d, Passed: %d\n",
-		len(checkFiles), ignore, miss, pass)
+		len(allFiles), ignore, miss, pass)
 
 	if !*disableSPDXPtr {
 		fmt.Printf("SPDX Total: %d, Missing: %d, Passed: %d\n",
 			len(checkFiles), spdx_miss, spdx_pass)
 	}
 
+	if *sbomPtr != "" {
+		writeSBOMFile(*sbomPtr, *sbomFormatPtr, *directoryPtr, results)
+	}
+
 	if miss != 0 || spdx_miss != 0 {
 		os.Exit(1)
 	}
 }
+
+// writeSBOMFile builds and writes an SPDX 2.3 SBOM for results, naming
+// the top-level Package after directory, to path in the given format.
+func writeSBOMFile(path, format, directory string, results []fileResult) {
+	abs, err := filepath.Abs(directory)
+	check(err)
+
+	doc, err := newSBOMDocument(filepath.Base(abs), results, time.Now())
+	check(err)
+
+	f, err := os.Create(path)
+	check(err)
+	defer f.Close()
+
+	check(writeSBOM(f, doc, format))
+}
@@ -0,0 +1,97 @@
+// Package licenses embeds a curated subset of common SPDX identifiers
+// (see data/spdx.json) so that lhc can render and match against any of
+// them instead of a small hardcoded set of license files. Use IDs to list
+// exactly which identifiers are supported; an identifier outside that set
+// returns an error from Lookup, the same as before this package existed.
+package licenses
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed data/spdx.json
+var spdxData []byte
+
+//go:embed data/exceptions.json
+var exceptionData []byte
+
+// Template holds the rendered text for an SPDX license: Header is the
+// short notice meant to be copied into a source file, Text is the full
+// license body.
+type Template struct {
+	ID     string
+	Name   string
+	Header string
+	Text   string
+}
+
+type exception struct {
+	ID   string
+	Name string
+	Text string
+}
+
+var (
+	bySPDXID   map[string]Template
+	exceptions map[string]exception
+)
+
+func init() {
+	var templates []Template
+	if err := json.Unmarshal(spdxData, &templates); err != nil {
+		panic("licenses: invalid embedded spdx.json: " + err.Error())
+	}
+	bySPDXID = make(map[string]Template, len(templates))
+	for _, t := range templates {
+		bySPDXID[t.ID] = t
+	}
+
+	var exceptionList []exception
+	if err := json.Unmarshal(exceptionData, &exceptionList); err != nil {
+		panic("licenses: invalid embedded exceptions.json: " + err.Error())
+	}
+	exceptions = make(map[string]exception, len(exceptionList))
+	for _, e := range exceptionList {
+		exceptions[e.ID] = e
+	}
+}
+
+// Lookup returns the Template for spdxID, which may be a plain SPDX
+// identifier ("MIT") or carry a WITH exception ("Apache-2.0 WITH
+// LLVM-exception"), in which case the exception text is appended to both
+// Header and Text and Template.ID echoes the full expression.
+func Lookup(spdxID string) (Template, error) {
+	id, exceptionID, hasException := strings.Cut(spdxID, " WITH ")
+
+	t, ok := bySPDXID[id]
+	if !ok {
+		return Template{}, fmt.Errorf("licenses: unknown SPDX identifier %q", id)
+	}
+
+	if hasException {
+		e, ok := exceptions[exceptionID]
+		if !ok {
+			return Template{}, fmt.Errorf("licenses: unknown SPDX exception %q", exceptionID)
+		}
+		t.ID = spdxID
+		t.Header = t.Header + "\n\n" + e.Text
+		t.Text = t.Text + "\n\n" + e.Text
+	}
+
+	return t, nil
+}
+
+// IDs returns every supported SPDX identifier, sorted, for use by
+// --list-licenses.
+func IDs() []string {
+	ids := make([]string, 0, len(bySPDXID))
+	for id := range bySPDXID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
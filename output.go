@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// jsonResult is the shape emitted per file by --format json.
+type jsonResult struct {
+	Path    string `json:"path"`
+	License string `json:"license"`
+	SPDX    string `json:"spdx"`
+	Status  string `json:"status"`
+}
+
+// status reports "pass" if a file passed every enabled check, else
+// "fail".
+func (r fileResult) status(disableSPDX bool) string {
+	if !r.headerPass || (!disableSPDX && !r.spdxPass) {
+		return "fail"
+	}
+	return "pass"
+}
+
+// printResults renders results in format ("text", "json", "junit", or
+// "github"). It returns an error for an unknown format or a failed
+// JSON/JUnit encoding; it does not count failures.
+func printResults(results []fileResult, format string, disableSPDX bool) error {
+	switch format {
+	case "", "text":
+		printText(results, disableSPDX)
+	case "json":
+		return printJSON(results, disableSPDX)
+	case "junit":
+		return printJUnit(results, disableSPDX)
+	case "github":
+		printGithub(results, disableSPDX)
+	default:
+		return fmt.Errorf("unknown --format %q, want \"text\", \"json\", \"junit\", or \"github\"", format)
+	}
+	return nil
+}
+
+func printText(results []fileResult, disableSPDX bool) {
+	for _, r := range results {
+		line := "✔"
+		if !r.headerPass {
+			line = "✘"
+		}
+		if !disableSPDX {
+			if r.spdxPass {
+				line += "✔"
+			} else {
+				line += "✘"
+			}
+		}
+		fmt.Println(line, r.path)
+	}
+}
+
+func printJSON(results []fileResult, disableSPDX bool) error {
+	out := make([]jsonResult, len(results))
+	for i, r := range results {
+		out[i] = jsonResult{
+			Path:    r.path,
+			License: r.license,
+			SPDX:    r.spdxID,
+			Status:  r.status(disableSPDX),
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// junitTestSuite is the subset of the JUnit XML schema printJUnit emits,
+// marshaled via encoding/xml so that paths or messages containing XML
+// metacharacters are escaped correctly instead of merely Go-quoted.
+type junitTestSuite struct {
+	XMLName xml.Name        `xml:"testsuite"`
+	Name    string          `xml:"name,attr"`
+	Tests   int             `xml:"tests,attr"`
+	Cases   []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// printJUnit renders results as a JUnit XML report, one testcase per
+// file, so CI systems that already understand JUnit can surface missing
+// headers without a bespoke parser.
+func printJUnit(results []fileResult, disableSPDX bool) error {
+	suite := junitTestSuite{Name: "lhc", Tests: len(results)}
+	for _, r := range results {
+		tc := junitTestCase{Name: r.path, ClassName: "lhc.license-header"}
+		if r.status(disableSPDX) == "fail" {
+			tc.Failure = &junitFailure{
+				Message: "missing or mismatched license header",
+				Text:    fmt.Sprintf("license=%s spdx=%s", r.license, r.spdxID),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	out, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(`<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Println(string(out))
+	return nil
+}
+
+// printGithub renders results as GitHub Actions workflow commands so
+// missing headers show up as inline annotations on the changed file.
+func printGithub(results []fileResult, disableSPDX bool) {
+	for _, r := range results {
+		if r.status(disableSPDX) == "fail" {
+			fmt.Printf("::error file=%s::missing or mismatched license header\n", r.path)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"testing"
+)
+
+// captureStdout redirects os.Stdout for the duration of fn and returns
+// everything written to it.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	fn()
+	w.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestPrintJUnitEscapesSpecialCharacters(t *testing.T) {
+	results := []fileResult{
+		{path: `weird"dir/b.go`, license: `a & b`, spdxID: "", headerPass: false, spdxPass: false},
+	}
+
+	out := captureStdout(t, func() {
+		if err := printJUnit(results, false); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal([]byte(out), &suite); err != nil {
+		t.Fatalf("printJUnit produced invalid XML: %v\noutput:\n%s", err, out)
+	}
+	if len(suite.Cases) != 1 || suite.Cases[0].Name != `weird"dir/b.go` {
+		t.Errorf("testcase name round-tripped as %+v, want original path preserved", suite.Cases)
+	}
+}
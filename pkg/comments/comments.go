@@ -0,0 +1,95 @@
+// Package comments provides a registry of per-language comment styles so
+// that header extraction and header insertion can be implemented once and
+// reused across every file extension lhc understands, instead of hardcoding
+// a small fixed set of comment tokens.
+package comments
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Style describes how comments are written in a given language. Line holds
+// every token that introduces a line comment (some languages support more
+// than one). BlockStart and
+// BlockEnd hold the open/close tokens for a block comment; both are empty
+// for languages that only support line comments.
+type Style struct {
+	Line       []string
+	BlockStart string
+	BlockEnd   string
+}
+
+// registry maps a lowercased file extension (including the leading dot) to
+// the comment style used by that language. Extensions are intentionally
+// case-insensitive since some platforms preserve the case a file was
+// created with.
+var registry = map[string]Style{
+	".go":   {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".c":    {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".h":    {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".cc":   {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".cpp":  {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".java": {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".js":   {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".ts":   {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".rs":   {Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"},
+	".py":   {Line: []string{"#"}},
+	".rb":   {Line: []string{"#"}, BlockStart: "=begin", BlockEnd: "=end"},
+	".sh":   {Line: []string{"#"}},
+	".bash": {Line: []string{"#"}},
+	".yaml": {Line: []string{"#"}},
+	".yml":  {Line: []string{"#"}},
+	".toml": {Line: []string{"#"}},
+	".xml":  {BlockStart: "<!--", BlockEnd: "-->"},
+	".html": {BlockStart: "<!--", BlockEnd: "-->"},
+	".hs":   {Line: []string{"--"}, BlockStart: "{-", BlockEnd: "-}"},
+	".sql":  {Line: []string{"--"}, BlockStart: "/*", BlockEnd: "*/"},
+	".lua":  {Line: []string{"--"}, BlockStart: "--[[", BlockEnd: "]]"},
+}
+
+// StyleForFile returns the comment style registered for filename's
+// extension and true, or the zero Style and false if the extension is not
+// registered.
+func StyleForFile(filename string) (Style, bool) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	style, ok := registry[ext]
+	return style, ok
+}
+
+// Register adds or overrides the comment style used for ext (a file
+// extension including the leading dot, e.g. ".proto") in the shared
+// registry, so StyleForFile picks it up. Used to apply comment styles
+// declared in a user's config file.
+func Register(ext string, style Style) {
+	registry[strings.ToLower(ext)] = style
+}
+
+// IsComment reports whether line begins a line comment or a block comment
+// in the given style.
+func IsComment(style Style, line string) bool {
+	for _, token := range style.Line {
+		if strings.HasPrefix(line, token) {
+			return true
+		}
+	}
+	if style.BlockStart != "" && strings.HasPrefix(line, style.BlockStart) {
+		return true
+	}
+	return false
+}
+
+// TrimComment strips any comment token known to style from the left and
+// right of line, along with surrounding whitespace.
+func TrimComment(style Style, line string) string {
+	for _, token := range style.Line {
+		line = strings.TrimPrefix(line, token)
+	}
+	if style.BlockStart != "" {
+		line = strings.TrimPrefix(line, style.BlockStart)
+	}
+	if style.BlockEnd != "" {
+		line = strings.Split(line, style.BlockEnd)[0]
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+}
@@ -0,0 +1,46 @@
+package comments
+
+import "testing"
+
+func TestStyleForFileKnownAndUnknownExtension(t *testing.T) {
+	style, ok := StyleForFile("main.go")
+	if !ok || style.BlockStart != "/*" {
+		t.Errorf("StyleForFile(main.go) = (%+v, %v), want Go style, true", style, ok)
+	}
+
+	if _, ok := StyleForFile("README.proto"); ok {
+		t.Errorf("StyleForFile(.proto) = ok, want unregistered before Register")
+	}
+}
+
+func TestRegisterAddsAndOverridesStyle(t *testing.T) {
+	Register(".proto", Style{Line: []string{"//"}})
+	style, ok := StyleForFile("service.proto")
+	if !ok || len(style.Line) != 1 || style.Line[0] != "//" {
+		t.Errorf("StyleForFile(.proto) after Register = (%+v, %v), want registered style", style, ok)
+	}
+
+	Register(".py", Style{Line: []string{"#"}, BlockStart: "\"\"\"", BlockEnd: "\"\"\""})
+	style, _ = StyleForFile("script.PY")
+	if style.BlockStart != "\"\"\"" {
+		t.Errorf("Register(.py) did not override the built-in style: %+v", style)
+	}
+}
+
+func TestIsCommentAndTrimComment(t *testing.T) {
+	style := Style{Line: []string{"//"}, BlockStart: "/*", BlockEnd: "*/"}
+
+	if !IsComment(style, "// hello") || !IsComment(style, "/* hello") {
+		t.Errorf("IsComment should recognize line and block comment openers")
+	}
+	if IsComment(style, "package main") {
+		t.Errorf("IsComment should not recognize non-comment lines")
+	}
+
+	if got := TrimComment(style, "// hello "); got != "hello" {
+		t.Errorf("TrimComment(line) = %q, want %q", got, "hello")
+	}
+	if got := TrimComment(style, "/* hello */"); got != "hello" {
+		t.Errorf("TrimComment(block) = %q, want %q", got, "hello")
+	}
+}
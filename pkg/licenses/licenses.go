@@ -0,0 +1,100 @@
+// Package licenses normalizes license header text and scores how closely
+// it matches a template, so that minor re-flowing, punctuation, or
+// boilerplate differences don't produce false "missing header" results.
+package licenses
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultThreshold is the token-set containment score at or above which a header
+// is considered a match for a template when it isn't exactly equal after
+// normalization.
+const DefaultThreshold = 0.9
+
+var (
+	bulletRe      = regexp.MustCompile(`(?m)^\s*(?:[-*•]|\(?[0-9]+[.)]|\(?[a-zA-Z][.)])\s+`)
+	copyrightRe   = regexp.MustCompile(`(?im)^[ \t]*copyright\s*(?:\(c\)|©)?\s*(?:[0-9]{4}(?:\s*[-,]\s*[0-9]{4})*)?\s*.*`)
+	allRightsRe   = regexp.MustCompile(`(?i)all rights reserved\.?`)
+	yearRangeRe   = regexp.MustCompile(`\b[0-9]{4}\s*-\s*[0-9]{4}\b`)
+	punctuationRe = regexp.MustCompile(`[^\w\s]`)
+	whitespaceRe  = regexp.MustCompile(`\s+`)
+
+	smartQuotes = strings.NewReplacer(
+		"‘", "'", "’", "'",
+		"“", `"`, "”", `"`,
+		"–", "-", "—", "-", "−", "-",
+	)
+)
+
+// Normalize lowercases text, canonicalizes smart quotes and unicode
+// dashes, drops copyright lines, "All rights reserved" boilerplate and
+// year ranges, strips list bullets/numbering and punctuation, and
+// collapses whitespace. The result is a normalized token stream suitable
+// for exact or fuzzy comparison between two license headers.
+func Normalize(text string) string {
+	s := smartQuotes.Replace(text)
+	s = strings.ToLower(s)
+	s = copyrightRe.ReplaceAllString(s, "")
+	s = allRightsRe.ReplaceAllString(s, "")
+	s = yearRangeRe.ReplaceAllString(s, "")
+	s = bulletRe.ReplaceAllString(s, "")
+	s = punctuationRe.ReplaceAllString(s, " ")
+	s = whitespaceRe.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
+}
+
+// Match compares header against template after normalizing both, using
+// DefaultThreshold for the fuzzy score. See MatchWithThreshold.
+func Match(header, template string) (name string, score float64) {
+	return MatchWithThreshold(header, template, DefaultThreshold)
+}
+
+// MatchWithThreshold compares header against template after normalizing
+// both. It returns "exact" with a score of 1 when the normalized token
+// streams are identical, "fuzzy" with the token-set containment score of
+// template's tokens within header's tokens when that score is at least
+// threshold, or "" with that score otherwise.
+func MatchWithThreshold(header, template string, threshold float64) (name string, score float64) {
+	normalizedHeader := Normalize(header)
+	normalizedTemplate := Normalize(template)
+
+	if normalizedHeader == normalizedTemplate {
+		return "exact", 1
+	}
+
+	score = containment(normalizedHeader, normalizedTemplate)
+	if score >= threshold {
+		return "fuzzy", score
+	}
+	return "", score
+}
+
+// containment returns the fraction of template's tokens that also appear
+// in header, i.e. a containment score rather than a symmetric Jaccard
+// score, since a header may legitimately include extra boilerplate (a
+// project name, contributor list) around the license template itself.
+func containment(header, template string) float64 {
+	headerTokens := tokenSet(header)
+	templateTokens := strings.Fields(template)
+	if len(templateTokens) == 0 {
+		return 0
+	}
+
+	found := 0
+	for _, t := range templateTokens {
+		if headerTokens[t] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(templateTokens))
+}
+
+func tokenSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range strings.Fields(s) {
+		set[t] = true
+	}
+	return set
+}
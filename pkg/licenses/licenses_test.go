@@ -0,0 +1,42 @@
+package licenses
+
+import "testing"
+
+func TestNormalizeStripsCopyrightAndBoilerplate(t *testing.T) {
+	got := Normalize("Copyright (c) 2020-2024 Jane Doe\nAll Rights Reserved.\nMIT License")
+	want := "mit license"
+	if got != want {
+		t.Errorf("Normalize() = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeIgnoresBulletsPunctuationAndCase(t *testing.T) {
+	a := Normalize("1. Redistributions of source code must retain the notice.")
+	b := Normalize("Redistributions of source code must retain the notice")
+	if a != b {
+		t.Errorf("Normalize bullet/punctuation mismatch: %q != %q", a, b)
+	}
+}
+
+func TestMatchExact(t *testing.T) {
+	name, score := Match("MIT License", "MIT License")
+	if name != "exact" || score != 1 {
+		t.Errorf("Match(identical) = (%q, %v), want (\"exact\", 1)", name, score)
+	}
+}
+
+func TestMatchFuzzyWithExtraBoilerplate(t *testing.T) {
+	template := "Permission is hereby granted to use this software"
+	header := "Copyright (c) 2024 Acme\n\nPermission is hereby granted to use this software, provided notice is kept."
+	name, score := Match(header, template)
+	if name != "fuzzy" {
+		t.Errorf("Match(header with extra text) = (%q, %v), want fuzzy match", name, score)
+	}
+}
+
+func TestMatchWithThresholdRejectsBelowThreshold(t *testing.T) {
+	name, score := MatchWithThreshold("totally unrelated text", "Permission is hereby granted to use this software", 0.9)
+	if name != "" {
+		t.Errorf("MatchWithThreshold(unrelated) = (%q, %v), want no match", name, score)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// sbomFile is one SPDX File element: a scanned file together with the
+// license lhc concluded for it and a checksum for integrity.
+type sbomFile struct {
+	Path             string
+	SPDXID           string
+	LicenseConcluded string
+	SHA1             string
+}
+
+// sbomDocument is the subset of an SPDX 2.3 document lhc emits: a single
+// Package grouping every scanned file as a File element.
+type sbomDocument struct {
+	Name        string
+	Namespace   string
+	PackageName string
+	Created     time.Time
+	Files       []sbomFile
+}
+
+// newSBOMDocument builds an sbomDocument from the files lhc scanned,
+// computing a SHA1 checksum for each and falling back to NOASSERTION for
+// files with no accepted license.
+func newSBOMDocument(packageName string, results []fileResult, created time.Time) (*sbomDocument, error) {
+	doc := &sbomDocument{
+		Name:        packageName + "-sbom",
+		Namespace:   "https://spdx.org/spdxdocs/" + packageName + "-" + created.Format("20060102150405"),
+		PackageName: packageName,
+		Created:     created,
+	}
+
+	for i, r := range results {
+		sum, err := sha1File(r.path)
+		if err != nil {
+			return nil, err
+		}
+
+		// r.license is whatever was passed to -license (an SPDX id or a
+		// license file path); only a resolved SPDX id is a valid value for
+		// an SPDX 2.3 license expression field, so fall back to
+		// NOASSERTION rather than leak a file path into the SBOM.
+		license := resolveSPDXID(r.license)
+		if license == "" {
+			license = "NOASSERTION"
+		}
+
+		doc.Files = append(doc.Files, sbomFile{
+			Path:             r.path,
+			SPDXID:           fmt.Sprintf("SPDXRef-File-%d", i+1),
+			LicenseConcluded: license,
+			SHA1:             sum,
+		})
+	}
+
+	return doc, nil
+}
+
+// sha1File returns the hex-encoded SHA1 checksum of path's contents, as
+// required for an SPDX File's Checksum field.
+func sha1File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeSBOM renders doc to w in the requested format ("tag-value" or
+// "json").
+func writeSBOM(w io.Writer, doc *sbomDocument, format string) error {
+	switch format {
+	case "json":
+		return writeSBOMJSON(w, doc)
+	case "tag-value", "":
+		return writeSBOMTagValue(w, doc)
+	default:
+		return fmt.Errorf("unknown --sbom-format %q, want \"tag-value\" or \"json\"", format)
+	}
+}
+
+// writeSBOMTagValue renders doc as an SPDX 2.3 tag-value document.
+func writeSBOMTagValue(w io.Writer, doc *sbomDocument) error {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "SPDXVersion: SPDX-2.3")
+	fmt.Fprintln(b, "DataLicense: CC0-1.0")
+	fmt.Fprintln(b, "SPDXID: SPDXRef-DOCUMENT")
+	fmt.Fprintf(b, "DocumentName: %s\n", doc.Name)
+	fmt.Fprintf(b, "DocumentNamespace: %s\n", doc.Namespace)
+	fmt.Fprintf(b, "Created: %s\n", doc.Created.UTC().Format(time.RFC3339))
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "PackageName: "+doc.PackageName)
+	fmt.Fprintln(b, "SPDXID: SPDXRef-Package")
+	fmt.Fprintln(b, "PackageDownloadLocation: NOASSERTION")
+	fmt.Fprintln(b, "PackageLicenseDeclared: NOASSERTION")
+
+	for _, f := range doc.Files {
+		fmt.Fprintln(b)
+		fmt.Fprintf(b, "FileName: ./%s\n", f.Path)
+		fmt.Fprintf(b, "SPDXID: %s\n", f.SPDXID)
+		fmt.Fprintf(b, "FileChecksum: SHA1: %s\n", f.SHA1)
+		fmt.Fprintf(b, "LicenseConcluded: %s\n", f.LicenseConcluded)
+		fmt.Fprintf(b, "LicenseInfoInFile: %s\n", f.LicenseConcluded)
+		fmt.Fprintln(b, "Relationship: SPDXRef-Package CONTAINS "+f.SPDXID)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// spdxJSON is the subset of the SPDX 2.3 JSON schema lhc populates.
+type spdxJSON struct {
+	SPDXVersion       string                 `json:"spdxVersion"`
+	DataLicense       string                 `json:"dataLicense"`
+	SPDXID            string                 `json:"SPDXID"`
+	Name              string                 `json:"name"`
+	DocumentNamespace string                 `json:"documentNamespace"`
+	CreationInfo      spdxCreateInfo         `json:"creationInfo"`
+	Files             []spdxJSONFile         `json:"files"`
+	Packages          []spdxJSONPkg          `json:"packages"`
+	Relationships     []spdxJSONRelationship `json:"relationships"`
+}
+
+type spdxCreateInfo struct {
+	Created time.Time `json:"created"`
+}
+
+type spdxJSONFile struct {
+	SPDXID             string         `json:"SPDXID"`
+	FileName           string         `json:"fileName"`
+	Checksums          []spdxChecksum `json:"checksums"`
+	LicenseConcluded   string         `json:"licenseConcluded"`
+	LicenseInfoInFiles []string       `json:"licenseInfoInFiles"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxJSONPkg struct {
+	SPDXID           string `json:"SPDXID"`
+	Name             string `json:"name"`
+	DownloadLocation string `json:"downloadLocation"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+}
+
+type spdxJSONRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// writeSBOMJSON renders doc as an SPDX 2.3 JSON document.
+func writeSBOMJSON(w io.Writer, doc *sbomDocument) error {
+	out := spdxJSON{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              doc.Name,
+		DocumentNamespace: doc.Namespace,
+		CreationInfo:      spdxCreateInfo{Created: doc.Created.UTC()},
+		Packages: []spdxJSONPkg{{
+			SPDXID:           "SPDXRef-Package",
+			Name:             doc.PackageName,
+			DownloadLocation: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+		}},
+	}
+
+	for _, f := range doc.Files {
+		out.Files = append(out.Files, spdxJSONFile{
+			SPDXID:             f.SPDXID,
+			FileName:           "./" + f.Path,
+			Checksums:          []spdxChecksum{{Algorithm: "SHA1", ChecksumValue: f.SHA1}},
+			LicenseConcluded:   f.LicenseConcluded,
+			LicenseInfoInFiles: []string{f.LicenseConcluded},
+		})
+		out.Relationships = append(out.Relationships, spdxJSONRelationship{
+			SPDXElementID:      "SPDXRef-Package",
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: f.SPDXID,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,52 @@
+package main
+
+import "sync"
+
+// scanFiles processes files with a pool of jobs workers, reading each
+// file exactly once via scanHeader to extract both the license header
+// and any declared SPDX identifier, then checking both against
+// acceptedLicenses. Results are returned in the same order as files.
+func scanFiles(files []string, jobs int, acceptedLicenses []License, disableSPDX bool) []fileResult {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	results := make([]fileResult, len(files))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = scanOneFile(files[i], acceptedLicenses, disableSPDX)
+			}
+		}()
+	}
+
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// scanOneFile reads path once and checks it against acceptedLicenses.
+func scanOneFile(path string, acceptedLicenses []License, disableSPDX bool) fileResult {
+	headerText, spdxID := scanHeader(path)
+	license := accepted_license(headerText, acceptedLicenses)
+
+	result := fileResult{
+		path:       path,
+		license:    license,
+		spdxID:     spdxID,
+		headerPass: license != "",
+	}
+	if !disableSPDX {
+		result.spdxPass = spdxID != "" && spdxID == license
+	}
+	return result
+}